@@ -0,0 +1,181 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy controls what AsyncWriter does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// BlockOnFull blocks WriteRecord until there is room in the queue.
+	BlockOnFull OverflowPolicy = iota
+
+	// DropNewest discards the record that triggered the overflow,
+	// leaving the queue unchanged.
+	DropNewest
+
+	// DropOldest discards the oldest queued record to make room for
+	// the new one.
+	DropOldest
+)
+
+// AsyncWriter wraps a RecordWriter so that WriteRecord enqueues the
+// record on a bounded channel and returns immediately, leaving the
+// (potentially slow) inner writer to drain it on a background
+// goroutine. This keeps expensive writers, such as file or network
+// sinks, off the logging hot path.
+type AsyncWriter struct {
+	inner  RecordWriter
+	queue  chan Record
+	policy OverflowPolicy
+	onDrop func(Record)
+
+	dropped uint64
+
+	closeMu sync.Mutex
+	closed  bool
+	closing chan struct{} // closed by Close to unblock a pending send
+	done    chan struct{} // closed by drain once it has exited
+}
+
+// NewAsyncWriter returns an AsyncWriter that buffers up to bufSize
+// records for inner. onDrop, if non-nil, is called (on the writing
+// goroutine) whenever a record is discarded because the queue is full;
+// it is only relevant for DropOldest/DropNewest, since BlockOnFull never
+// drops a record.
+func NewAsyncWriter(inner RecordWriter, bufSize int, onDrop func(Record)) *AsyncWriter {
+	w := &AsyncWriter{
+		inner:   inner,
+		queue:   make(chan Record, bufSize),
+		policy:  BlockOnFull,
+		onDrop:  onDrop,
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.drain()
+	return w
+}
+
+// SetPolicy sets the overflow policy used when the queue is full. It is
+// meant to be called once, before the writer starts receiving records.
+func (w *AsyncWriter) SetPolicy(policy OverflowPolicy) {
+	w.policy = policy
+}
+
+// MinLogLevel passes through to inner if it implements MinLevelWriter,
+// so that an AsyncWriter can itself be registered as a MinLevelWriter.
+func (w *AsyncWriter) MinLogLevel() Level {
+	if mlw, ok := w.inner.(MinLevelWriter); ok {
+		return mlw.MinLogLevel()
+	}
+	return UNSPECIFIED
+}
+
+// WriteRecord enqueues rec for the background goroutine to write,
+// applying the configured OverflowPolicy if the queue is full. Once
+// Close has been called, WriteRecord is a no-op: queue is never closed
+// out from under a concurrent sender, so there's nothing further to do
+// but drop the record.
+func (w *AsyncWriter) WriteRecord(rec Record) {
+	w.closeMu.Lock()
+	closed := w.closed
+	w.closeMu.Unlock()
+	if closed {
+		return
+	}
+
+	switch w.policy {
+	case BlockOnFull:
+		select {
+		case w.queue <- rec:
+		case <-w.closing:
+		}
+	case DropNewest:
+		select {
+		case w.queue <- rec:
+		default:
+			w.drop(rec)
+		}
+	case DropOldest:
+		for {
+			select {
+			case w.queue <- rec:
+				return
+			default:
+			}
+			select {
+			case old := <-w.queue:
+				w.drop(old)
+			default:
+			}
+		}
+	}
+}
+
+func (w *AsyncWriter) drop(rec Record) {
+	atomic.AddUint64(&w.dropped, 1)
+	if w.onDrop != nil {
+		w.onDrop(rec)
+	}
+}
+
+// Dropped returns the number of records discarded so far due to queue
+// overflow.
+func (w *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Queued returns the number of records currently buffered, waiting to
+// be written.
+func (w *AsyncWriter) Queued() int {
+	return len(w.queue)
+}
+
+// Close stops accepting new records, flushes everything already queued
+// to the inner writer, and returns once the background goroutine has
+// exited. queue itself is never closed, so a WriteRecord call racing
+// with Close can never panic on a send to a closed channel. Such a
+// record is written only if it is queued before drain's final pass
+// empties queue; otherwise it is either discarded by the closed check
+// at the top of WriteRecord or left in queue unwritten, and in neither
+// case is it counted by Dropped.
+func (w *AsyncWriter) Close() error {
+	w.closeMu.Lock()
+	if w.closed {
+		w.closeMu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.closeMu.Unlock()
+
+	close(w.closing)
+	<-w.done
+	return nil
+}
+
+// drain is the background goroutine that writes queued records to
+// inner. Once closing is signalled it drains whatever's left in queue
+// without blocking, then exits and closes done.
+func (w *AsyncWriter) drain() {
+	defer close(w.done)
+	for {
+		select {
+		case rec := <-w.queue:
+			w.inner.WriteRecord(rec)
+		case <-w.closing:
+			for {
+				select {
+				case rec := <-w.queue:
+					w.inner.WriteRecord(rec)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
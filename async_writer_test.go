@@ -0,0 +1,150 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingWriter records how many times WriteRecord was called, and can
+// simulate a slow sink like a file or network write.
+type countingWriter struct {
+	mu    sync.Mutex
+	n     int
+	delay time.Duration
+}
+
+func (w *countingWriter) WriteRecord(Record) {
+	if w.delay > 0 {
+		time.Sleep(w.delay)
+	}
+	w.mu.Lock()
+	w.n++
+	w.mu.Unlock()
+}
+
+func (w *countingWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.n
+}
+
+func testRecord(msg string) Record {
+	return NewRecord(0, INFO, "test", msg)
+}
+
+func TestAsyncWriter_DropNewestDropsUnderPressure(t *testing.T) {
+	inner := &countingWriter{delay: 10 * time.Millisecond}
+	var dropped []Record
+	w := NewAsyncWriter(inner, 1, func(rec Record) {
+		dropped = append(dropped, rec)
+	})
+	w.SetPolicy(DropNewest)
+	defer w.Close()
+
+	for i := 0; i < 20; i++ {
+		w.WriteRecord(testRecord(fmt.Sprintf("msg-%d", i)))
+	}
+
+	if len(dropped) == 0 {
+		t.Fatal("expected at least one record to be dropped under pressure")
+	}
+	if got := w.Dropped(); got != uint64(len(dropped)) {
+		t.Fatalf("Dropped() = %d, want %d", got, len(dropped))
+	}
+}
+
+func TestAsyncWriter_CloseFlushesQueue(t *testing.T) {
+	inner := &countingWriter{}
+	w := NewAsyncWriter(inner, 100, nil)
+
+	const n = 50
+	for i := 0; i < n; i++ {
+		w.WriteRecord(testRecord(fmt.Sprintf("msg-%d", i)))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got := inner.count(); got != n {
+		t.Fatalf("inner write count = %d, want %d", got, n)
+	}
+}
+
+func TestAsyncWriter_WriteRecordAfterCloseDoesNotPanic(t *testing.T) {
+	inner := &countingWriter{}
+	w := NewAsyncWriter(inner, 1, nil)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Racing a WriteRecord against (or after) Close must never panic
+	// with "send on closed channel".
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.WriteRecord(testRecord("after close"))
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkFormattingWriter_Sync(b *testing.B) {
+	writer := NewFormattingWriter(discardWriter{}, nil)
+	rec := testRecord("benchmark message")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writer.WriteRecord(rec)
+	}
+}
+
+func BenchmarkAsyncWriter_OverFormattingWriter(b *testing.B) {
+	writer := NewAsyncWriter(NewFormattingWriter(discardWriter{}, nil), 1024, nil)
+	defer writer.Close()
+	rec := testRecord("benchmark message")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writer.WriteRecord(rec)
+	}
+}
+
+// slowNetworkSink stands in for a real network sink (syslog/GELF/etc.)
+// whose Write call is dominated by round-trip latency rather than CPU.
+type slowNetworkSink struct{}
+
+func (slowNetworkSink) WriteRecord(Record) {
+	time.Sleep(time.Millisecond)
+}
+
+func BenchmarkNetworkSink_Sync(b *testing.B) {
+	sink := slowNetworkSink{}
+	rec := testRecord("benchmark message")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sink.WriteRecord(rec)
+	}
+}
+
+func BenchmarkAsyncWriter_OverNetworkSink(b *testing.B) {
+	writer := NewAsyncWriter(slowNetworkSink{}, 4096, nil)
+	defer writer.Close()
+	rec := testRecord("benchmark message")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writer.WriteRecord(rec)
+	}
+}
+
+// discardWriter implements io.Writer by discarding everything, so the
+// formatting benchmarks measure formatting overhead rather than I/O.
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,338 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fileConfig is the declarative schema accepted by ConfigureFromFile and
+// ConfigureFromBytes. It mirrors the XML-configured approach from
+// log4go, but expressed as Go-idiomatic YAML/JSON.
+type fileConfig struct {
+	RootLevel  string                     `yaml:"root_level" json:"root_level"`
+	Modules    map[string]string          `yaml:"modules" json:"modules"`
+	Formatters map[string]formatterConfig `yaml:"formatters" json:"formatters"`
+	Writers    map[string]writerConfig    `yaml:"writers" json:"writers"`
+}
+
+// formatterConfig names a Formatter and its options, for reference by a
+// writerConfig's "formatter" field.
+type formatterConfig struct {
+	Type   string `yaml:"type" json:"type"`
+	Layout string `yaml:"layout" json:"layout"`
+}
+
+// writerConfig describes one entry in the top-level "writers" map.
+type writerConfig struct {
+	Type string `yaml:"type" json:"type"`
+
+	// MinLevel is the minimum level this writer receives, as accepted
+	// by ParseLevel. Empty means TRACE (everything).
+	MinLevel string `yaml:"min_level" json:"min_level"`
+
+	// Formatter names an entry in the top-level "formatters" map. If
+	// empty, the writer falls back to its own default rendering.
+	Formatter string `yaml:"formatter" json:"formatter"`
+
+	// Path is used by type "file".
+	Path string `yaml:"path" json:"path"`
+
+	// MaxSize, MaxBackups, MaxAge, Compress, and Daily configure
+	// rotation for type "file", matching Log's fields of the same
+	// name. MaxAge is parsed with time.ParseDuration.
+	MaxSize    int64  `yaml:"max_size" json:"max_size"`
+	MaxBackups int    `yaml:"max_backups" json:"max_backups"`
+	MaxAge     string `yaml:"max_age" json:"max_age"`
+	Compress   bool   `yaml:"compress" json:"compress"`
+	Daily      bool   `yaml:"daily" json:"daily"`
+
+	// Target is used by the network types ("syslog", "gelf",
+	// "net-json"), as a URL such as "udp://host:514".
+	Target string `yaml:"target" json:"target"`
+
+	// BufSize, set on type "async", wraps the writer named by Inner.
+	Inner   string `yaml:"inner" json:"inner"`
+	BufSize int    `yaml:"buf_size" json:"buf_size"`
+
+	// Writers, set on type "tee", names the writers (built elsewhere in
+	// this same config) to fan records out to, in order.
+	Writers []string `yaml:"writers" json:"writers"`
+}
+
+// isComposite reports whether wc.Type wraps other named writers (and so
+// must be built after them) rather than being a leaf writer.
+func (wc writerConfig) isComposite() bool {
+	return wc.Type == "async" || wc.Type == "tee"
+}
+
+// ConfigureFromFile reads a declarative YAML or JSON configuration from
+// path (the format is chosen by its extension) and applies it, exactly
+// as ConfigureFromBytes does.
+func ConfigureFromFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read logging config %q: %v", path, err)
+	}
+	format := "yaml"
+	if filepath.Ext(path) == ".json" {
+		format = "json"
+	}
+	return ConfigureFromBytes(data, format)
+}
+
+// ConfigureFromBytes parses data as the given format ("yaml" or "json")
+// and builds the writer tree, module levels, and root level it
+// describes. Writers are registered by name via RegisterWriter;
+// re-applying a config that reuses a writer's name atomically replaces
+// it via replaceWriter, which keeps a writer registered under some name
+// throughout the swap so no log record in flight is dropped.
+func ConfigureFromBytes(data []byte, format string) error {
+	var cfg fileConfig
+	var err error
+	switch format {
+	case "json":
+		err = json.Unmarshal(data, &cfg)
+	case "yaml", "":
+		err = yaml.Unmarshal(data, &cfg)
+	default:
+		return fmt.Errorf("unknown logging config format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse logging config: %v", err)
+	}
+	return applyFileConfig(cfg)
+}
+
+// writerBuildMu serialises ConfigureFromBytes calls, so that a reload
+// racing another reload can't interleave removals and registrations.
+var writerBuildMu sync.Mutex
+
+// netWriterBuilder builds a RecordWriter for the "syslog", "gelf", and
+// "net-json" writer types. It's nil until the netwriter package's init()
+// calls RegisterNetWriterBuilder, since netwriter itself imports this
+// package; a direct import here would create an import cycle.
+var netWriterBuilder func(kind, target string) (RecordWriter, error)
+
+// RegisterNetWriterBuilder installs the builder used for the "syslog",
+// "gelf", and "net-json" writer types in ConfigureFromFile and
+// ConfigureFromBytes. Callers who want those types available must
+// blank-import github.com/ericsnowcurrently/juju-loggo/netwriter, whose
+// init() calls this.
+func RegisterNetWriterBuilder(build func(kind, target string) (RecordWriter, error)) {
+	netWriterBuilder = build
+}
+
+func applyFileConfig(cfg fileConfig) error {
+	writerBuildMu.Lock()
+	defer writerBuildMu.Unlock()
+
+	built := make(map[string]RecordWriter, len(cfg.Writers))
+
+	// Leaf writers (those that don't reference another named writer)
+	// are built first. Composites may refer to leaves or to other
+	// composites, so they are built in passes until a pass builds
+	// nothing new; this finds every dependency in built regardless of
+	// map iteration order, and a composite still unbuilt after the last
+	// pass reports the error from its last attempt.
+	for name, wc := range cfg.Writers {
+		if wc.isComposite() {
+			continue
+		}
+		writer, err := buildNamedWriter(name, wc, cfg.Formatters, built)
+		if err != nil {
+			return err
+		}
+		built[name] = writer
+	}
+	var compositeErr error
+	for progressed := true; progressed; {
+		progressed, compositeErr = false, nil
+		for name, wc := range cfg.Writers {
+			if _, done := built[name]; done || !wc.isComposite() {
+				continue
+			}
+			writer, err := buildNamedWriter(name, wc, cfg.Formatters, built)
+			if err != nil {
+				compositeErr = err
+				continue
+			}
+			built[name] = writer
+			progressed = true
+		}
+	}
+	if compositeErr != nil {
+		return compositeErr
+	}
+
+	for name, wc := range cfg.Writers {
+		minLevel := TRACE
+		if wc.MinLevel != "" {
+			level, ok := ParseLevel(wc.MinLevel)
+			if !ok {
+				return fmt.Errorf("writer %q: unknown level %q", name, wc.MinLevel)
+			}
+			minLevel = level
+		}
+		if err := replaceWriter(name, built[name], minLevel); err != nil {
+			return fmt.Errorf("writer %q: %v", name, err)
+		}
+	}
+
+	if cfg.RootLevel != "" {
+		level, ok := ParseLevel(cfg.RootLevel)
+		if !ok {
+			return fmt.Errorf("unknown root_level %q", cfg.RootLevel)
+		}
+		GetLogger("").SetLogLevel(level)
+	}
+	for module, levelName := range cfg.Modules {
+		level, ok := ParseLevel(levelName)
+		if !ok {
+			return fmt.Errorf("module %q: unknown level %q", module, levelName)
+		}
+		GetLogger(module).SetLogLevel(level)
+	}
+	return nil
+}
+
+// buildNamedWriter resolves wc's formatter and builds the writer it
+// describes, wrapping any error with the writer's name for context.
+func buildNamedWriter(name string, wc writerConfig, formatters map[string]formatterConfig, built map[string]RecordWriter) (RecordWriter, error) {
+	formatter, err := resolveFormatter(formatters, wc.Formatter)
+	if err != nil {
+		return nil, fmt.Errorf("writer %q: %v", name, err)
+	}
+	writer, err := buildWriter(wc, formatter, built)
+	if err != nil {
+		return nil, fmt.Errorf("writer %q: %v", name, err)
+	}
+	return writer, nil
+}
+
+// replaceWriter registers writer under name such that a writer is
+// registered under some name at every point in time, so a record
+// dispatched mid-swap is never dropped: the new writer goes up under a
+// temporary name first, the old registration under name is removed
+// only once the new writer is already live, and then the new writer
+// also takes over the canonical name before the temporary one is torn
+// down.
+func replaceWriter(name string, writer RecordWriter, minLevel Level) error {
+	tempName := name + ".replacing"
+
+	if err := RegisterWriter(tempName, writer, minLevel); err != nil {
+		return err
+	}
+	RemoveWriter(name)
+	if err := RegisterWriter(name, writer, minLevel); err != nil {
+		RemoveWriter(tempName)
+		return err
+	}
+	RemoveWriter(tempName)
+	return nil
+}
+
+func resolveFormatter(formatters map[string]formatterConfig, name string) (Formatter, error) {
+	if name == "" {
+		return nil, nil
+	}
+	fc, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("formatter %q not defined", name)
+	}
+	switch fc.Type {
+	case "pattern":
+		return NewPatternFormatter(fc.Layout)
+	case "json":
+		return &JSONFormatter{}, nil
+	case "basic":
+		return &BasicFormatter{}, nil
+	case "minimal":
+		return &MinimalFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("formatter %q: unknown type %q", name, fc.Type)
+	}
+}
+
+func buildWriter(wc writerConfig, formatter Formatter, built map[string]RecordWriter) (RecordWriter, error) {
+	switch wc.Type {
+	case "stdio":
+		return NewFormattingWriter(os.Stdout, formatter), nil
+	case "file":
+		return buildFileWriter(wc, formatter)
+	case "syslog", "gelf", "net-json":
+		if netWriterBuilder == nil {
+			return nil, fmt.Errorf("writer type %q requires blank-importing"+
+				" github.com/ericsnowcurrently/juju-loggo/netwriter", wc.Type)
+		}
+		return netWriterBuilder(wc.Type, wc.Target)
+	case "async":
+		inner, ok := built[wc.Inner]
+		if !ok {
+			return nil, fmt.Errorf("async writer refers to unknown inner writer %q", wc.Inner)
+		}
+		bufSize := wc.BufSize
+		if bufSize <= 0 {
+			bufSize = 1024
+		}
+		return NewAsyncWriter(inner, bufSize, nil), nil
+	case "tee":
+		if len(wc.Writers) == 0 {
+			return nil, fmt.Errorf("tee writer requires a non-empty writers list")
+		}
+		subs := make([]RecordWriter, 0, len(wc.Writers))
+		for _, subName := range wc.Writers {
+			sub, ok := built[subName]
+			if !ok {
+				return nil, fmt.Errorf("tee writer refers to unknown writer %q", subName)
+			}
+			// TeeWriter only delivers to sub-writers that expose a
+			// min level; wrap any that don't so they still receive
+			// everything.
+			if _, ok := sub.(MinLevelWriter); !ok {
+				sub = NewMinLevelWriter(sub, TRACE)
+			}
+			subs = append(subs, sub)
+		}
+		return NewTeeWriter(subs...), nil
+	default:
+		return nil, fmt.Errorf("unknown writer type %q", wc.Type)
+	}
+}
+
+func buildFileWriter(wc writerConfig, formatter Formatter) (RecordWriter, error) {
+	if wc.Path == "" {
+		return nil, fmt.Errorf("file writer requires a path")
+	}
+	if wc.MaxSize <= 0 && !wc.Daily {
+		target, err := os.OpenFile(wc.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return NewFormattingWriter(target, formatter), nil
+	}
+
+	var maxAge time.Duration
+	if wc.MaxAge != "" {
+		age, err := time.ParseDuration(wc.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_age %q: %v", wc.MaxAge, err)
+		}
+		maxAge = age
+	}
+	rotating, err := NewRotatingFileWriter(wc.Path, wc.MaxSize, wc.MaxBackups, maxAge, wc.Compress, wc.Daily)
+	if err != nil {
+		return nil, err
+	}
+	rotating.Formatter = formatter
+	return rotating, nil
+}
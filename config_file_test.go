@@ -0,0 +1,74 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+import "testing"
+
+func TestConfigureFromBytes_YAML(t *testing.T) {
+	data := []byte(`
+root_level: WARNING
+modules:
+  myapp.db: DEBUG
+formatters:
+  pretty:
+    type: pattern
+    layout: "[%L] %M"
+writers:
+  console:
+    type: stdio
+    formatter: pretty
+    min_level: INFO
+`)
+	if err := ConfigureFromBytes(data, "yaml"); err != nil {
+		t.Fatalf("ConfigureFromBytes() error = %v", err)
+	}
+
+	if got := GetLogger("").LogLevel(); got != WARNING {
+		t.Errorf("root level = %v, want %v", got, WARNING)
+	}
+	if got := GetLogger("myapp.db").LogLevel(); got != DEBUG {
+		t.Errorf("myapp.db level = %v, want %v", got, DEBUG)
+	}
+}
+
+func TestConfigureFromBytes_JSON(t *testing.T) {
+	data := []byte(`{"root_level": "ERROR"}`)
+	if err := ConfigureFromBytes(data, "json"); err != nil {
+		t.Fatalf("ConfigureFromBytes() error = %v", err)
+	}
+	if got := GetLogger("").LogLevel(); got != ERROR {
+		t.Errorf("root level = %v, want %v", got, ERROR)
+	}
+}
+
+func TestConfigureFromBytes_UnknownFormat(t *testing.T) {
+	if err := ConfigureFromBytes([]byte("{}"), "toml"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
+
+func TestConfigureFromBytes_Tee(t *testing.T) {
+	data := []byte(`
+writers:
+  stdout:
+    type: stdio
+  all:
+    type: tee
+    writers: ["stdout"]
+`)
+	if err := ConfigureFromBytes(data, "yaml"); err != nil {
+		t.Fatalf("ConfigureFromBytes() error = %v", err)
+	}
+}
+
+func TestConfigureFromBytes_UnknownWriterType(t *testing.T) {
+	data := []byte(`
+writers:
+  bogus:
+    type: not-a-real-type
+`)
+	if err := ConfigureFromBytes(data, "yaml"); err == nil {
+		t.Fatal("expected an error for an unknown writer type")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSONFormatter_Format(t *testing.T) {
+	rec := NewRecordWithFields(0, ERROR, "mod.sub", "it broke", map[string]interface{}{
+		"request_id": "abc123",
+	})
+	rec.Err = errors.New("boom")
+
+	line := (&JSONFormatter{}).Format(rec)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &got); err != nil {
+		t.Fatalf("Format() did not produce valid JSON: %v\nline: %s", err, line)
+	}
+
+	for key, want := range map[string]interface{}{
+		"level":  "ERROR",
+		"module": "mod.sub",
+		"msg":    "it broke",
+		"err":    "boom",
+	} {
+		if got[key] != want {
+			t.Errorf("field %q = %v, want %v", key, got[key], want)
+		}
+	}
+
+	fields, ok := got["fields"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a fields object, got %#v", got["fields"])
+	}
+	if fields["request_id"] != "abc123" {
+		t.Errorf("fields[request_id] = %v, want %q", fields["request_id"], "abc123")
+	}
+}
@@ -22,26 +22,65 @@ type Log struct {
 
 	// NewWriter creates a new logging writer for a specified target.
 	NewWriter func(io.Writer) RecordWriter
+
+	// Layout, if set, selects a PatternFormatter for the writer returned
+	// by writer() instead of the default Record.String() rendering. It
+	// takes precedence over NewWriter so that a layout picked from
+	// ConfigureLoggers-style config doesn't require a custom Formatter.
+	Layout string
+
+	// MaxSize, MaxBackups, MaxAge, Compress, and Daily configure rotation
+	// of the file opened for Path. MaxSize of zero with Daily unset (the
+	// defaults) leaves the file unrotated, matching existing callers that
+	// never set them.
+	MaxSize    int64
+	MaxBackups int
+	MaxAge     time.Duration
+	Compress   bool
+	Daily      bool
 }
 
 // writer returns a logging writer for the specified target.
 func (log Log) writer(target io.Writer) RecordWriter {
-	if log.NewWriter == nil {
-		return NewFormattingWriter(target, nil)
+	if log.NewWriter != nil && log.Layout == "" {
+		return log.NewWriter(target)
+	}
+	return NewFormattingWriter(target, log.formatter())
+}
+
+// formatter returns the Formatter selected by Layout, or nil to fall
+// back to Record.String().
+func (log Log) formatter() Formatter {
+	if log.Layout == "" {
+		return nil
+	}
+	formatter, err := NewPatternFormatter(log.Layout)
+	if err != nil {
+		return nil
 	}
-	return log.NewWriter(target)
+	return formatter
 }
 
 // Start starts logging using the given Context.
 func (log *Log) Start(ctx *Context) error {
 	if log.Path != "" {
 		path := ctx.AbsPath(log.Path)
-		target, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
-		if err != nil {
-			return err
+		var writer RecordWriter
+		if log.MaxSize > 0 || log.Daily {
+			rotating, err := NewRotatingFileWriter(path, log.MaxSize, log.MaxBackups, log.MaxAge, log.Compress, log.Daily)
+			if err != nil {
+				return err
+			}
+			rotating.Formatter = log.formatter()
+			writer = rotating
+		} else {
+			target, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+			if err != nil {
+				return err
+			}
+			writer = log.GetLogWriter(target)
 		}
-		writer := log.GetLogWriter(target)
-		err = loggo.RegisterWriter("logfile", writer, loggo.TRACE)
+		err := loggo.RegisterWriter("logfile", writer, loggo.TRACE)
 		if err != nil {
 			return err
 		}
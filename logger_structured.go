@@ -0,0 +1,68 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+// Infow logs a structured message at INFO level. The kv arguments are
+// interpreted as alternating key, value pairs (e.g. "request_id", reqID,
+// "user_id", userID) and are attached to the Record as Fields so that
+// writers such as JSONFormatter can emit them without the caller having
+// to build the map by hand.
+func (logger Logger) Infow(message string, kv ...interface{}) {
+	logger.logw(1, INFO, message, kv...)
+}
+
+// Warnw logs a structured message at WARNING level. See Infow for the
+// kv convention.
+func (logger Logger) Warnw(message string, kv ...interface{}) {
+	logger.logw(1, WARNING, message, kv...)
+}
+
+// Errorw logs a structured message at ERROR level. See Infow for the kv
+// convention. If kv contains the key "err" with an error value, it is
+// also set as the Record's Err rather than merged into Fields.
+func (logger Logger) Errorw(message string, kv ...interface{}) {
+	logger.logw(1, ERROR, message, kv...)
+}
+
+// logw is the shared implementation behind Infow/Warnw/Errorw.
+func (logger Logger) logw(calldepth int, level Level, message string, kv ...interface{}) {
+	if !logger.IsLevelEnabled(level) {
+		return
+	}
+	fields, err := fieldsFromKV(kv)
+	rec := NewRecordWithFields(calldepth+1, level, logger.Name(), message, fields)
+	rec.Err = err
+	logger.impl.write(rec)
+}
+
+// fieldsFromKV turns a flat list of alternating key/value pairs into a
+// Fields map. A trailing odd key is kept with a nil value rather than
+// dropped, so that a caller's logging mistake is visible in the output
+// instead of silently losing data. If one of the values is an error
+// keyed "err", it is pulled out and returned separately for Record.Err.
+func fieldsFromKV(kv []interface{}) (map[string]interface{}, error) {
+	if len(kv) == 0 {
+		return nil, nil
+	}
+	fields := make(map[string]interface{}, (len(kv)+1)/2)
+	var recErr error
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = "!BADKEY"
+		}
+		var value interface{}
+		if i+1 < len(kv) {
+			value = kv[i+1]
+		}
+		if key == "err" {
+			if asErr, ok := value.(error); ok {
+				recErr = asErr
+				continue
+			}
+		}
+		fields[key] = value
+	}
+	return fields, recErr
+}
@@ -0,0 +1,147 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package netwriter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/ericsnowcurrently/juju-loggo"
+)
+
+// gelfChunkSize is the maximum UDP payload GELF recommends per chunk,
+// leaving room for the chunk header and typical MTUs.
+const gelfChunkSize = 1420
+
+// gelfMagic identifies a GELF chunk, per the Graylog wire format.
+var gelfMagic = [2]byte{0x1e, 0x0f}
+
+// GELFWriter is a loggo.RecordWriter that sends records to a Graylog
+// collector as GELF messages, chunked over UDP when they exceed a
+// single datagram.
+type GELFWriter struct {
+	dialer *dialer
+	host   string
+}
+
+// gelfMessage is the JSON payload shape GELF expects.
+type gelfMessage struct {
+	Version      string  `json:"version"`
+	Host         string  `json:"host"`
+	ShortMessage string  `json:"short_message"`
+	FullMessage  string  `json:"full_message,omitempty"`
+	Timestamp    float64 `json:"timestamp"`
+	Level        int     `json:"level"`
+	Module       string  `json:"_module"`
+	File         string  `json:"_file"`
+	Line         int     `json:"_line"`
+}
+
+// NewGELFWriter returns a GELFWriter that writes to address over udp.
+// host identifies the originating host in the "host" field.
+func NewGELFWriter(address, host string) *GELFWriter {
+	return &GELFWriter{
+		dialer: newDialer("udp", address),
+		host:   host,
+	}
+}
+
+// WriteRecord formats rec as a GELF message and sends it, chunking if
+// it's larger than a single UDP datagram should carry.
+func (w *GELFWriter) WriteRecord(rec loggo.Record) {
+	data, err := w.encode(rec)
+	if err != nil {
+		return
+	}
+	if len(data) <= gelfChunkSize {
+		w.dialer.write(data)
+		return
+	}
+	w.writeChunked(data)
+}
+
+func (w *GELFWriter) encode(rec loggo.Record) ([]byte, error) {
+	msg := gelfMessage{
+		Version:      "1.1",
+		Host:         w.host,
+		ShortMessage: rec.Message,
+		Timestamp:    float64(rec.Timestamp.UnixNano()) / 1e9,
+		Level:        severityForLevel(rec.Level),
+		Module:       rec.Module,
+		File:         filepath.Base(rec.Filename),
+		Line:         rec.Line,
+	}
+
+	// Marshal the fixed fields, then splice in the "_"-prefixed
+	// structured fields and any error, since json.Marshal can't easily
+	// merge a struct with a dynamic set of extra keys.
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	var flat map[string]interface{}
+	if err := json.Unmarshal(raw, &flat); err != nil {
+		return nil, err
+	}
+	for key, value := range rec.Fields {
+		flat["_"+key] = value
+	}
+	if rec.Err != nil {
+		flat["full_message"] = rec.Err.Error()
+	}
+	return json.Marshal(flat)
+}
+
+// writeChunked splits data into GELF chunks and sends each one, per the
+// Graylog UDP chunking spec: a 2-byte magic, an 8-byte random message
+// ID, and a (sequence, total) byte pair ahead of each chunk's payload.
+func (w *GELFWriter) writeChunked(data []byte) {
+	total := (len(data) + gelfChunkSize - 1) / gelfChunkSize
+	if total > 128 {
+		// GELF caps a message at 128 chunks; drop rather than send a
+		// message the collector will reject outright.
+		return
+	}
+
+	var msgID [8]byte
+	if _, err := rand.Read(msgID[:]); err != nil {
+		return
+	}
+
+	for seq := 0; seq < total; seq++ {
+		start := seq * gelfChunkSize
+		end := start + gelfChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		var buf bytes.Buffer
+		buf.Write(gelfMagic[:])
+		buf.Write(msgID[:])
+		binary.Write(&buf, binary.BigEndian, uint8(seq))
+		binary.Write(&buf, binary.BigEndian, uint8(total))
+		buf.Write(data[start:end])
+
+		w.dialer.write(buf.Bytes())
+	}
+}
+
+// gzipCompress is available for callers that want to send compressed
+// (rather than chunked) GELF payloads over TCP, where chunking doesn't
+// apply.
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package netwriter
+
+import (
+	"github.com/ericsnowcurrently/juju-loggo"
+)
+
+// NetJSONWriter is a loggo.RecordWriter that sends each record as a
+// newline-delimited JSON object (via loggo.JSONFormatter) over TCP or
+// UDP.
+type NetJSONWriter struct {
+	dialer    *dialer
+	formatter loggo.JSONFormatter
+}
+
+// NewNetJSONWriter returns a NetJSONWriter that writes to address over
+// network (one of "tcp" or "udp").
+func NewNetJSONWriter(network, address string) *NetJSONWriter {
+	return &NetJSONWriter{
+		dialer: newDialer(network, address),
+	}
+}
+
+// WriteRecord formats rec as JSON and sends it, newline-terminated, to
+// the configured collector, reconnecting with backoff if needed.
+func (w *NetJSONWriter) WriteRecord(rec loggo.Record) {
+	line := w.formatter.Format(rec) + "\n"
+	w.dialer.write([]byte(line))
+}
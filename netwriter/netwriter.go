@@ -0,0 +1,117 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package netwriter provides loggo.RecordWriter implementations that
+// ship log records to remote collectors: syslog, newline-delimited JSON
+// over TCP/UDP, and Graylog GELF. They are the modern, Go-idiomatic
+// equivalent of log4go's socklog.
+package netwriter
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ericsnowcurrently/juju-loggo"
+)
+
+// dialer is the shared reconnect-with-backoff logic used by all the
+// writers in this package. Each writer owns one and calls getConn() to get
+// (and lazily (re)establish) the current connection.
+type dialer struct {
+	network string
+	address string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	nextTry time.Time
+	backoff time.Duration
+}
+
+const (
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+func newDialer(network, address string) *dialer {
+	return &dialer{
+		network: network,
+		address: address,
+		backoff: minBackoff,
+	}
+}
+
+// getConn returns the current connection, dialing (or redialing) it if
+// necessary. It returns nil, without error, if a previous dial attempt
+// failed recently enough that the backoff hasn't elapsed yet.
+func (d *dialer) getConn() net.Conn {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn != nil {
+		return d.conn
+	}
+	if time.Now().Before(d.nextTry) {
+		return nil
+	}
+
+	conn, err := net.DialTimeout(d.network, d.address, 5*time.Second)
+	if err != nil {
+		d.nextTry = time.Now().Add(d.backoff)
+		d.backoff *= 2
+		if d.backoff > maxBackoff {
+			d.backoff = maxBackoff
+		}
+		return nil
+	}
+	d.backoff = minBackoff
+	d.conn = conn
+	return conn
+}
+
+// fail drops the current connection so the next getConn call redials,
+// and schedules the backoff. Call it when a write to the connection
+// fails.
+func (d *dialer) fail() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn != nil {
+		d.conn.Close()
+		d.conn = nil
+	}
+	d.nextTry = time.Now().Add(d.backoff)
+	d.backoff *= 2
+	if d.backoff > maxBackoff {
+		d.backoff = maxBackoff
+	}
+}
+
+// write sends data over the current connection, tearing it down on
+// error so the next call reconnects.
+func (d *dialer) write(data []byte) {
+	conn := d.getConn()
+	if conn == nil {
+		return
+	}
+	if _, err := conn.Write(data); err != nil {
+		d.fail()
+	}
+}
+
+// severityForLevel maps a loggo.Level to its closest RFC 5424 severity.
+func severityForLevel(level loggo.Level) int {
+	switch level {
+	case loggo.CRITICAL:
+		return 2 // Critical
+	case loggo.ERROR:
+		return 3 // Error
+	case loggo.WARNING:
+		return 4 // Warning
+	case loggo.INFO:
+		return 6 // Informational
+	case loggo.DEBUG, loggo.TRACE:
+		return 7 // Debug
+	default:
+		return 5 // Notice
+	}
+}
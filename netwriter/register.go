@@ -0,0 +1,77 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package netwriter
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ericsnowcurrently/juju-loggo"
+)
+
+// init registers New as the builder loggo.ConfigureFromFile and
+// ConfigureFromBytes use for the "syslog", "gelf", and "net-json"
+// writer types. loggo can't call into this package directly (this
+// package already imports loggo, and the reverse would be an import
+// cycle), so it exposes this hook instead; blank-importing this
+// package is what enables those writer types in a declarative config.
+func init() {
+	loggo.RegisterNetWriterBuilder(New)
+}
+
+// New builds a loggo.RecordWriter from a target URL such as
+// "udp://host:514" (syslog), "tcp://host:9000" (newline-delimited
+// JSON), or "gelf://host:12201" (Graylog GELF). It is the counterpart
+// to the "syslog=udp://host:514;INFO"-style entries loggo.Log.Config
+// parses: the scheme before "=" names the kind, and everything after is
+// handed to New.
+func New(kind, target string) (loggo.RecordWriter, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("netwriter: invalid target %q: %v", target, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("netwriter: target %q has no host", target)
+	}
+
+	switch kind {
+	case "syslog":
+		network := u.Scheme
+		if network == "" {
+			network = "udp"
+		}
+		return NewSyslogWriter(network, u.Host, ""), nil
+	case "net-json":
+		network := u.Scheme
+		if network == "" {
+			network = "tcp"
+		}
+		return NewNetJSONWriter(network, u.Host), nil
+	case "gelf":
+		return NewGELFWriter(u.Host, localHostname()), nil
+	default:
+		return nil, fmt.Errorf("netwriter: unknown writer kind %q", kind)
+	}
+}
+
+// RegisterWriter builds a writer with New from kind (e.g. "syslog") and
+// target (e.g. "udp://host:514") and registers it under name at the
+// given minimum level.
+func RegisterWriter(name, kind, target string, minLevel loggo.Level) error {
+	writer, err := New(kind, target)
+	if err != nil {
+		return err
+	}
+	return loggo.RegisterWriter(name, writer, minLevel)
+}
+
+func localHostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "-"
+	}
+	return strings.ToLower(name)
+}
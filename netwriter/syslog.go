@@ -0,0 +1,63 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package netwriter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ericsnowcurrently/juju-loggo"
+)
+
+// syslogFacilityUser is the RFC 5424 facility loggo records are tagged
+// with. There's no notion of facility in loggo itself, so we always use
+// "user-level messages" (1).
+const syslogFacilityUser = 1
+
+// SyslogWriter is a loggo.RecordWriter that ships records to a remote
+// syslog collector over UDP, TCP, or a Unix socket, formatted as RFC
+// 5424 messages.
+type SyslogWriter struct {
+	dialer *dialer
+	tag    string
+}
+
+// NewSyslogWriter returns a SyslogWriter that writes to address over
+// network (one of "udp", "tcp", or "unix"). tag identifies this process
+// in the syslog APP-NAME field; if empty, filepath.Base(os.Args[0]) is
+// used.
+func NewSyslogWriter(network, address, tag string) *SyslogWriter {
+	if tag == "" && len(os.Args) > 0 {
+		tag = filepath.Base(os.Args[0])
+	}
+	return &SyslogWriter{
+		dialer: newDialer(network, address),
+		tag:    tag,
+	}
+}
+
+// WriteRecord formats rec as an RFC 5424 message and sends it to the
+// configured syslog collector, reconnecting with backoff if needed.
+func (w *SyslogWriter) WriteRecord(rec loggo.Record) {
+	priority := syslogFacilityUser*8 + severityForLevel(rec.Level)
+	msg := fmt.Sprintf("<%d>1 %s %s %s - - - %s %s\n",
+		priority,
+		rec.Timestamp.UTC().Format(time.RFC3339),
+		hostname(),
+		w.tag,
+		rec.Module,
+		rec.Message,
+	)
+	w.dialer.write([]byte(msg))
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "-"
+	}
+	return name
+}
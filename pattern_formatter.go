@@ -0,0 +1,141 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PatternFormatter formats records according to a layout string such as
+//   "[%D %T] [%L] (%S) %M"
+// where each verb is replaced with the corresponding piece of the record:
+//
+//	%D             date, as 2006-01-02
+//	%T             time, as 15:04:05.000
+//	%L             level
+//	%N             module name
+//	%S             source, as file:line
+//	%M             message
+//	%{field:name}  the structured field "name", or "" if not present
+//	%%             a literal percent sign
+//
+// Anything in the layout that isn't a recognised verb is copied through
+// unchanged, so literal separators like the brackets and spaces above
+// are free to use.
+type PatternFormatter struct {
+	layout   string
+	segments []patternSegment
+}
+
+// patternSegment renders one piece of the compiled layout.
+type patternSegment func(rec Record, buf *strings.Builder)
+
+// NewPatternFormatter compiles layout into a Formatter. Compiling the
+// layout once, rather than re-parsing it on every call to Format, keeps
+// the formatter allocation-light on the hot logging path.
+func NewPatternFormatter(layout string) (Formatter, error) {
+	segments, err := compilePattern(layout)
+	if err != nil {
+		return nil, err
+	}
+	return &PatternFormatter{
+		layout:   layout,
+		segments: segments,
+	}, nil
+}
+
+// Format renders rec according to the compiled layout.
+func (pf *PatternFormatter) Format(rec Record) string {
+	var buf strings.Builder
+	for _, segment := range pf.segments {
+		segment(rec, &buf)
+	}
+	return buf.String()
+}
+
+func compilePattern(layout string) ([]patternSegment, error) {
+	var segments []patternSegment
+	var literal strings.Builder
+
+	flushLiteral := func() {
+		if literal.Len() == 0 {
+			return
+		}
+		text := literal.String()
+		segments = append(segments, func(rec Record, buf *strings.Builder) {
+			buf.WriteString(text)
+		})
+		literal.Reset()
+	}
+
+	runes := []rune(layout)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if c != '%' {
+			literal.WriteRune(c)
+			continue
+		}
+		if i+1 >= len(runes) {
+			return nil, fmt.Errorf("pattern %q ends with a dangling %%", layout)
+		}
+		i++
+		switch verb := runes[i]; verb {
+		case '%':
+			literal.WriteByte('%')
+		case 'D':
+			flushLiteral()
+			segments = append(segments, func(rec Record, buf *strings.Builder) {
+				buf.WriteString(rec.Timestamp.Format("2006-01-02"))
+			})
+		case 'T':
+			flushLiteral()
+			segments = append(segments, func(rec Record, buf *strings.Builder) {
+				buf.WriteString(rec.Timestamp.Format("15:04:05.000"))
+			})
+		case 'L':
+			flushLiteral()
+			segments = append(segments, func(rec Record, buf *strings.Builder) {
+				buf.WriteString(rec.Level.String())
+			})
+		case 'N':
+			flushLiteral()
+			segments = append(segments, func(rec Record, buf *strings.Builder) {
+				buf.WriteString(rec.Module)
+			})
+		case 'S':
+			flushLiteral()
+			segments = append(segments, func(rec Record, buf *strings.Builder) {
+				fmt.Fprintf(buf, "%s:%d", filepath.Base(rec.Filename), rec.Line)
+			})
+		case 'M':
+			flushLiteral()
+			segments = append(segments, func(rec Record, buf *strings.Builder) {
+				buf.WriteString(rec.Message)
+			})
+		case '{':
+			end := strings.IndexRune(string(runes[i:]), '}')
+			if end < 0 {
+				return nil, fmt.Errorf("pattern %q has an unterminated %%{ verb", layout)
+			}
+			spec := string(runes[i+1 : i+end])
+			i += end
+			if !strings.HasPrefix(spec, "field:") {
+				return nil, fmt.Errorf("pattern %q has unsupported verb %%{%s}", layout, spec)
+			}
+			name := spec[len("field:"):]
+			flushLiteral()
+			segments = append(segments, func(rec Record, buf *strings.Builder) {
+				if value, ok := rec.Fields[name]; ok {
+					fmt.Fprintf(buf, "%v", value)
+				}
+			})
+		default:
+			return nil, fmt.Errorf("pattern %q has unknown verb %%%c", layout, verb)
+		}
+	}
+	flushLiteral()
+	return segments, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPatternFormatter_Format(t *testing.T) {
+	formatter, err := NewPatternFormatter("[%L] (%N) %M")
+	if err != nil {
+		t.Fatalf("NewPatternFormatter() error = %v", err)
+	}
+
+	rec := NewRecord(0, WARNING, "some.module", "oh no")
+	got := formatter.Format(rec)
+	want := "[WARNING] (some.module) oh no"
+	if got != want {
+		t.Fatalf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestPatternFormatter_FieldVerb(t *testing.T) {
+	formatter, err := NewPatternFormatter("%M request_id=%{field:request_id}")
+	if err != nil {
+		t.Fatalf("NewPatternFormatter() error = %v", err)
+	}
+
+	rec := NewRecordWithFields(0, INFO, "mod", "hello", map[string]interface{}{
+		"request_id": "abc123",
+	})
+	got := formatter.Format(rec)
+	want := "hello request_id=abc123"
+	if got != want {
+		t.Fatalf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestPatternFormatter_RejectsUnknownVerb(t *testing.T) {
+	if _, err := NewPatternFormatter("%Q"); err == nil {
+		t.Fatal("expected an error for an unknown verb")
+	}
+}
+
+func TestPatternFormatter_RejectsDanglingPercent(t *testing.T) {
+	if _, err := NewPatternFormatter("trailing %"); err == nil {
+		t.Fatal("expected an error for a dangling %")
+	}
+}
+
+func TestPatternFormatter_LiteralPercent(t *testing.T) {
+	formatter, err := NewPatternFormatter("100%% done: %M")
+	if err != nil {
+		t.Fatalf("NewPatternFormatter() error = %v", err)
+	}
+	rec := NewRecord(0, INFO, "mod", "finished")
+	got := formatter.Format(rec)
+	if !strings.HasPrefix(got, "100% done:") {
+		t.Fatalf("Format() = %q, want prefix %q", got, "100% done:")
+	}
+}
@@ -30,6 +30,13 @@ type Record struct {
 
 	// Message is the requested log message.
 	Message string
+
+	// Fields holds the structured key/value context attached to the
+	// record, if any, via one of the Logger.*w methods.
+	Fields map[string]interface{}
+
+	// Err is the error associated with the record, if any.
+	Err error
 }
 
 // NewRecord creates a new log record for the given log level, module,
@@ -78,6 +85,24 @@ func NewRecordf(calldepth int, level Level, module, message string, args ...inte
 	return rec
 }
 
+// NewRecordWithFields creates a new log record exactly as NewRecord does,
+// but also attaches the given structured fields so that formatters and
+// writers aimed at log aggregators can surface them.
+func NewRecordWithFields(calldepth int, level Level, module, message string, fields map[string]interface{}) Record {
+	rec := NewRecord(calldepth+1, level, module, message)
+	rec.Fields = fields
+	return rec
+}
+
+// NewRecordfWithFields combines NewRecordf and NewRecordWithFields: the
+// message is formatted with Sprintf and the record carries the given
+// structured fields.
+func NewRecordfWithFields(calldepth int, level Level, module, message string, fields map[string]interface{}, args ...interface{}) Record {
+	rec := NewRecordf(calldepth+1, level, module, message, args...)
+	rec.Fields = fields
+	return rec
+}
+
 // String returns the default string representation of the log record.
 // The details are separated by spaces except for filename and line
 // which are separated by a colon. The timestamp is shown to second
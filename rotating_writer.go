@@ -0,0 +1,271 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package loggo
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFileWriter is a RecordWriter that writes formatted records to a
+// file, rotating it by size and/or by day, modelled on the FileLogWriter
+// rotation behaviour from log4go.
+type RotatingFileWriter struct {
+	// Path is the file that records are appended to. Rotated copies are
+	// written alongside it, suffixed with a timestamp.
+	Path string
+
+	// MaxSize is the size, in bytes, at which the file is rotated. Zero
+	// disables size-based rotation.
+	MaxSize int64
+
+	// MaxBackups is the number of rotated files to keep. Zero means
+	// keep them all.
+	MaxBackups int
+
+	// MaxAge is the maximum age of a rotated file before it is pruned.
+	// Zero means backups are never pruned by age.
+	MaxAge time.Duration
+
+	// Compress gzips rotated files once they are no longer being
+	// written to.
+	Compress bool
+
+	// Daily rotates the file at midnight (local time) in addition to
+	// any size-based rotation.
+	Daily bool
+
+	// Formatter renders each record before it is written. A nil
+	// Formatter falls back to Record.String().
+	Formatter Formatter
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	hupOnce     sync.Once
+	hupStopOnce sync.Once
+	hupCh       chan os.Signal
+}
+
+// NewRotatingFileWriter opens (creating if necessary) the file at path
+// and returns a RotatingFileWriter that writes to it, rotating according
+// to the given limits. It also installs a SIGHUP handler that reopens
+// the file, for compatibility with external tools like logrotate.
+func NewRotatingFileWriter(path string, maxSize int64, maxBackups int, maxAge time.Duration, compress, daily bool) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		Path:       path,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   compress,
+		Daily:      daily,
+	}
+	if err := w.openLocked(); err != nil {
+		return nil, err
+	}
+	w.watchHUP()
+	return w, nil
+}
+
+// WriteRecord formats rec and appends it to the current file, rotating
+// first if the write would exceed MaxSize or cross a day boundary and
+// Daily is set. It is safe to call concurrently.
+func (w *RotatingFileWriter) WriteRecord(rec Record) {
+	var line string
+	if w.Formatter == nil {
+		line = rec.String()
+	} else {
+		line = w.Formatter.Format(rec)
+	}
+	line += "\n"
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.shouldRotateLocked(rec.Timestamp, int64(len(line))) {
+		if err := w.rotateLocked(); err != nil {
+			fmt.Fprintf(os.Stderr, "loggo: failed to rotate %s: %v\n", w.Path, err)
+		}
+	}
+
+	n, err := io.WriteString(w.file, line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loggo: failed to write to %s: %v\n", w.Path, err)
+		return
+	}
+	w.size += int64(n)
+}
+
+// Close flushes and closes the underlying file and stops watching for
+// SIGHUP.
+func (w *RotatingFileWriter) Close() error {
+	w.stopHUP()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// reopenLocked closes and reopens the log file in place, for use by the
+// SIGHUP handler on platforms that support it. The caller must hold w.mu.
+func (w *RotatingFileWriter) reopenLocked() error {
+	if w.file != nil {
+		w.file.Close()
+	}
+	return w.openLocked()
+}
+
+func (w *RotatingFileWriter) shouldRotateLocked(now time.Time, nextWrite int64) bool {
+	if w.MaxSize > 0 && w.size+nextWrite > w.MaxSize {
+		return true
+	}
+	if w.Daily && now.YearDay() != w.openedAt.YearDay() || (w.Daily && now.Year() != w.openedAt.Year()) {
+		return true
+	}
+	return false
+}
+
+func (w *RotatingFileWriter) openLocked() error {
+	f, err := os.OpenFile(w.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	w.openedAt = time.Now()
+	return nil
+}
+
+// rotateLocked renames the current file aside and opens a fresh one in
+// its place, then prunes old backups. The caller must hold w.mu.
+func (w *RotatingFileWriter) rotateLocked() error {
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+
+	if _, err := os.Stat(w.Path); err == nil {
+		backup := uniqueBackupPath(w.Path)
+		if err := os.Rename(w.Path, backup); err != nil {
+			return err
+		}
+		if w.Compress {
+			go compressBackup(backup)
+		}
+	}
+
+	if err := w.openLocked(); err != nil {
+		return err
+	}
+	go w.pruneBackups()
+	return nil
+}
+
+// uniqueBackupPath returns a backup name for path that doesn't already
+// exist on disk. It starts from a second-resolution timestamp, which
+// collides when rotation happens more than once a second (e.g. under
+// a tight MaxSize), and falls back to an incrementing ".1", ".2", ...
+// suffix until it finds a name that's free.
+func uniqueBackupPath(path string) string {
+	base := fmt.Sprintf("%s.%s", path, time.Now().Format("20060102-150405"))
+	if _, err := os.Stat(base); os.IsNotExist(err) {
+		return base
+	}
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s.%d", base, i)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// pruneBackups removes rotated files beyond MaxBackups or older than
+// MaxAge. It runs without holding w.mu since it only touches files
+// other than the currently open one.
+func (w *RotatingFileWriter) pruneBackups() {
+	if w.MaxBackups <= 0 && w.MaxAge <= 0 {
+		return
+	}
+	dir := filepath.Dir(w.Path)
+	base := filepath.Base(w.Path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Strings(backups)
+
+	now := time.Now()
+	if w.MaxAge > 0 {
+		kept := backups[:0]
+		for _, path := range backups {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if now.Sub(info.ModTime()) > w.MaxAge {
+				os.Remove(path)
+				continue
+			}
+			kept = append(kept, path)
+		}
+		backups = kept
+	}
+	if w.MaxBackups > 0 && len(backups) > w.MaxBackups {
+		for _, path := range backups[:len(backups)-w.MaxBackups] {
+			os.Remove(path)
+		}
+	}
+}
+
+func compressBackup(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		return
+	}
+	if err := gz.Close(); err != nil {
+		return
+	}
+	os.Remove(path)
+}
@@ -0,0 +1,44 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+//go:build !windows
+
+package loggo
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchHUP installs a SIGHUP handler that reopens the log file, so that
+// tools like logrotate can rename the file out from under us and have
+// us pick up the new one without restarting the process.
+func (w *RotatingFileWriter) watchHUP() {
+	w.hupOnce.Do(func() {
+		w.hupCh = make(chan os.Signal, 1)
+		signal.Notify(w.hupCh, syscall.SIGHUP)
+		go func() {
+			for range w.hupCh {
+				w.mu.Lock()
+				if err := w.reopenLocked(); err != nil {
+					fmt.Fprintf(os.Stderr, "loggo: failed to reopen %s after SIGHUP: %v\n", w.Path, err)
+				}
+				w.mu.Unlock()
+			}
+		}()
+	})
+}
+
+// stopHUP stops and closes hupCh so the goroutine started in watchHUP
+// exits. It's idempotent, since Close calls it unconditionally on every
+// call rather than just the first.
+func (w *RotatingFileWriter) stopHUP() {
+	w.hupStopOnce.Do(func() {
+		if w.hupCh != nil {
+			signal.Stop(w.hupCh)
+			close(w.hupCh)
+		}
+	})
+}
@@ -0,0 +1,13 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+//go:build windows
+
+package loggo
+
+// watchHUP is a no-op on Windows, which has no SIGHUP. Callers that need
+// the file reopened there (e.g. after an external rotation) should
+// recreate the RotatingFileWriter instead.
+func (w *RotatingFileWriter) watchHUP() {}
+
+func (w *RotatingFileWriter) stopHUP() {}
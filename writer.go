@@ -84,12 +84,12 @@ func (fw *formattingWriter) WriteRecord(rec Record) {
 // in order.
 type TeeWriter struct {
 	combinedMinLevel Level
-	writers          []Writer
+	writers          []RecordWriter
 }
 
 // NewTeeWriter creates a new TeeWriter that will write to the given
 // writers, in the order they were provided.
-func NewTeeWriter(writers ...Writer) *TeeWriter {
+func NewTeeWriter(writers ...RecordWriter) *TeeWriter {
 	tw := &TeeWriter{
 		combinedMinLevel: UNSPECIFIED,
 		writers:          writers,
@@ -118,13 +118,14 @@ func (tw *TeeWriter) MinLogLevel() Level {
 	return tw.combinedMinLevel
 }
 
-// Write implements Writer, sending the message to each registered writer.
-func (tw *TeeWriter) Write(rec Record) {
+// WriteRecord implements RecordWriter, sending the message to each
+// registered writer.
+func (tw *TeeWriter) WriteRecord(rec Record) {
 	for _, w := range tw.writers {
 		if mlw, ok := w.(MinLevelWriter); !ok || !IsLevelEnabled(mlw, rec.Level) {
 			continue
 		}
-		w.Write(rec)
+		w.WriteRecord(rec)
 	}
 }
 
@@ -136,11 +137,11 @@ type ModuleWriter struct {
 	Name string
 }
 
-// Write writes the record to the wrapped writer, but only if the module
-// name matches.
+// WriteRecord writes the record to the wrapped writer, but only if the
+// module name matches.
 func (w *ModuleWriter) WriteRecord(rec Record) {
 	if rec.Module == w.Name {
-		w.RecordWriter.Write(rec)
+		w.RecordWriter.WriteRecord(rec)
 	}
 }
 